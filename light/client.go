@@ -0,0 +1,434 @@
+package light
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/line/ostracon/libs/log"
+	tmmath "github.com/line/ostracon/libs/math"
+	"github.com/line/ostracon/light/provider"
+	"github.com/line/ostracon/types"
+)
+
+const (
+	defaultMaxRetryAttempts    = 10
+	defaultMaxBlockLag         = 30 * time.Second
+	defaultWitnessPollInterval = 1 * time.Second
+)
+
+// mode distinguishes between the two ways the light client can verify that
+// it can trust a new header given one it already trusts.
+type mode byte
+
+const (
+	sequential mode = iota + 1
+	skipping
+)
+
+// DefaultTrustLevel is the minimum fraction of the trusted voter set that
+// the skipping verification algorithm requires to have signed an
+// intermediate header before it is willing to trust it.
+var DefaultTrustLevel = tmmath.Fraction{Numerator: 1, Denominator: 3}
+
+// TrustOptions are the parameters for the light client's initial trust in a
+// header at a given height.
+type TrustOptions struct {
+	// Period over which the light client can trust the header after it was
+	// verified. Must be smaller than the unbonding period of the chain.
+	Period time.Duration
+
+	// Height and Hash identify the trusted header the client is
+	// bootstrapped with.
+	Height int64
+	Hash   []byte
+}
+
+// Option sets a parameter on the light client.
+type Option func(*Client)
+
+// Logger sets the logger the light client uses.
+func Logger(l log.Logger) Option {
+	return func(c *Client) { c.logger = l }
+}
+
+// MaxRetryAttempts sets the number of attempts the client will make to
+// find a new witness/primary upon failure before giving up.
+func MaxRetryAttempts(max int) Option {
+	return func(c *Client) { c.maxRetryAttempts = max }
+}
+
+// MaxBlockLag sets the maximum amount of time a witness is allowed to lag
+// behind the primary's reported block time before the client treats a
+// height mismatch as evidence of a forward-lunatic attack rather than the
+// witness simply being slow. It defaults to 30s.
+func MaxBlockLag(d time.Duration) Option {
+	return func(c *Client) { c.maxBlockLag = d }
+}
+
+// SequentialVerification configures the light client to verify headers
+// sequentially, one height at a time. This is slower but doesn't require a
+// trust level.
+func SequentialVerification() Option {
+	return func(c *Client) { c.verificationMode = sequential }
+}
+
+// SkippingVerification configures the light client to attempt to skip as
+// many intermediate headers as possible, so long as at least trustLevel of
+// the last trusted voter set has signed the new header. It falls back to
+// bisection whenever that is not the case.
+func SkippingVerification(trustLevel tmmath.Fraction) Option {
+	return func(c *Client) {
+		c.verificationMode = skipping
+		c.trustLevel = trustLevel
+	}
+}
+
+// Client is a light client that verifies headers using a primary provider
+// and cross-checks them against a set of witnesses, backed by a VoterSet
+// derived per-height (rather than a ValidatorSet), as required by
+// Ostracon's proof-of-stake voter selection.
+type Client struct {
+	chainID          string
+	trustingPeriod   time.Duration
+	verificationMode mode
+	trustLevel       tmmath.Fraction
+	maxRetryAttempts int
+	maxBlockLag      time.Duration
+
+	// witnessPollInterval and sleep back examineForwardLunaticCandidate's
+	// wait loop. They're unexported and only overridden by tests (via
+	// export_test.go) so that loop can be driven without real wall-clock
+	// delay.
+	witnessPollInterval time.Duration
+	sleep               func(time.Duration)
+
+	voterParams *types.VoterParams
+
+	primary   provider.Provider
+	witnesses []provider.Provider
+
+	trustedStore Store
+	logger       log.Logger
+}
+
+// NewClient creates a new light client, fetching and verifying the header
+// at TrustOptions.Height from the primary against TrustOptions.Hash, then
+// storing it as the client's initial trusted header.
+func NewClient(
+	ctx context.Context,
+	chainID string,
+	trustOptions TrustOptions,
+	primary provider.Provider,
+	witnesses []provider.Provider,
+	trustedStore Store,
+	voterParams *types.VoterParams,
+	options ...Option,
+) (*Client, error) {
+	c := &Client{
+		chainID:             chainID,
+		trustingPeriod:      trustOptions.Period,
+		verificationMode:    sequential,
+		trustLevel:          DefaultTrustLevel,
+		maxRetryAttempts:    defaultMaxRetryAttempts,
+		maxBlockLag:         defaultMaxBlockLag,
+		witnessPollInterval: defaultWitnessPollInterval,
+		sleep:               time.Sleep,
+		voterParams:         voterParams,
+		primary:             primary,
+		witnesses:           witnesses,
+		trustedStore:        trustedStore,
+		logger:              log.NewNopLogger(),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	trustedBlock, err := c.primary.LightBlock(ctx, trustOptions.Height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch initial trusted header/voters: %w", err)
+	}
+
+	if !bytes.Equal(trustedBlock.Hash(), trustOptions.Hash) {
+		return nil, fmt.Errorf("trusted header hash %X does not match primary's header hash %X",
+			trustOptions.Hash, trustedBlock.Hash())
+	}
+
+	if err := verifyValidatorSetHash(trustedBlock); err != nil {
+		return nil, fmt.Errorf("invalid initial trusted block: %w", err)
+	}
+
+	if err := c.compareFirstHeaderWithWitnesses(ctx, trustedBlock.SignedHeader); err != nil {
+		return nil, err
+	}
+
+	if err := c.trustedStore.SaveLightBlock(trustedBlock); err != nil {
+		return nil, fmt.Errorf("failed to save trusted header: %w", err)
+	}
+
+	return c, nil
+}
+
+// Witnesses returns the current set of witness providers.
+func (c *Client) Witnesses() []provider.Provider {
+	return c.witnesses
+}
+
+// VerifyLightBlockAtHeight fetches the light block at the given height from
+// the primary, verifies it against the latest trusted header using the
+// client's configured verification mode, and cross-checks it against every
+// witness before returning it.
+func (c *Client) VerifyLightBlockAtHeight(ctx context.Context, height int64, now time.Time) (*types.LightBlock, error) {
+	if height <= 0 {
+		return nil, fmt.Errorf("negative or zero height %d", height)
+	}
+
+	if lb, err := c.trustedStore.LightBlock(height); err == nil {
+		return lb, nil
+	}
+
+	trustedBlock, err := c.latestTrustedBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest trusted block: %w", err)
+	}
+
+	newBlock, err := c.primary.LightBlock(ctx, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch light block from primary at height %d: %w", height, err)
+	}
+
+	var trace []*types.LightBlock
+	switch c.verificationMode {
+	case skipping:
+		trace, err = c.verifySkipping(ctx, c.primary, trustedBlock, newBlock, now)
+	default:
+		trace, err = c.verifySequential(ctx, trustedBlock, newBlock, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.detectDivergence(ctx, trace, newBlock, now); err != nil {
+		return nil, err
+	}
+
+	if err := c.trustedStore.SaveLightBlock(newBlock); err != nil {
+		return nil, fmt.Errorf("failed to save verified light block: %w", err)
+	}
+
+	return newBlock, nil
+}
+
+// proofHashFromHeader returns the per-height value the client passes to
+// types.SelectVoter to elect that height's voter set: the header's own
+// hash. It is not derived from a VRF proof. Ostracon selects a fresh voter
+// set every block, so unlike Tendermint's ValidatorSet, a VoterSet can't be
+// assumed stable between two heights and must be reconstructed from the
+// header being verified.
+func proofHashFromHeader(sh *types.SignedHeader) []byte {
+	return sh.Header.Hash()
+}
+
+// verifyValidatorSetHash checks that a light block's ValidatorSet actually
+// hashes to the value its own header committed to. Without this, a
+// provider could pair a genuinely signed header with a forged
+// ValidatorSet: the header/commit would still check out, but SelectVoter
+// would then dutifully derive a VoterSet full of attacker-controlled keys
+// from it, and that forged VoterSet would go on to be trusted as the basis
+// for the next bisection step. This is the same invariant upstream
+// Tendermint's light client enforces in verifyNewHeaderAndVals.
+func verifyValidatorSetHash(lb *types.LightBlock) error {
+	if !bytes.Equal(lb.ValidatorSet.Hash(), lb.Header.ValidatorsHash) {
+		return fmt.Errorf("validator set hash %X does not match header's validators_hash %X at height %d",
+			lb.ValidatorSet.Hash(), lb.Header.ValidatorsHash, lb.Height)
+	}
+	return nil
+}
+
+func (c *Client) latestTrustedBlock() (*types.LightBlock, error) {
+	height, err := c.trustedStore.LastLightBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+	return c.trustedStore.LightBlock(height)
+}
+
+// verifySequential verifies newLightBlock against trustedBlock by checking
+// every height in between, one at a time, using each height's own voter
+// set. It returns the full trace of light blocks it verified along the
+// way, trustedBlock and newLightBlock included, so that a subsequent
+// trace-based conflicting-header check has something to walk.
+func (c *Client) verifySequential(
+	ctx context.Context,
+	trustedBlock *types.LightBlock,
+	newLightBlock *types.LightBlock,
+	now time.Time,
+) ([]*types.LightBlock, error) {
+	var (
+		cur   = trustedBlock
+		cnt   = newLightBlock.Height - trustedBlock.Height
+		trace = []*types.LightBlock{trustedBlock}
+	)
+	for i := int64(1); i <= cnt; i++ {
+		height := trustedBlock.Height + i
+
+		next := newLightBlock
+		if height != newLightBlock.Height {
+			var err error
+			next, err = c.primary.LightBlock(ctx, height)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch intermediate light block at height %d: %w", height, err)
+			}
+		}
+
+		if err := c.verifyHeight(cur, next, now); err != nil {
+			return nil, fmt.Errorf("verifying height %d failed: %w", height, err)
+		}
+		trace = append(trace, next)
+		cur = next
+	}
+	return trace, nil
+}
+
+// verifyHeight checks that at least the client's trust level of the voter
+// set selected for next's own height signed next. Apart from requiring next
+// to be higher and later than trustedBlock, it does not check next against
+// trustedBlock's validator or voter set.
+func (c *Client) verifyHeight(trustedBlock, next *types.LightBlock, now time.Time) error {
+	if err := next.SignedHeader.ValidateBasic(c.chainID); err != nil {
+		return fmt.Errorf("invalid header: %w", err)
+	}
+
+	if next.Height <= trustedBlock.Height {
+		return fmt.Errorf("expected new header height %d to be greater than trusted height %d",
+			next.Height, trustedBlock.Height)
+	}
+
+	if !next.Time.After(trustedBlock.Time) {
+		return fmt.Errorf("expected new header time %v to be after trusted header time %v",
+			next.Time, trustedBlock.Time)
+	}
+
+	if now.Before(next.Time) {
+		return fmt.Errorf("new header has a time from the future: %v (now: %v)", next.Time, now)
+	}
+
+	if err := verifyValidatorSetHash(next); err != nil {
+		return err
+	}
+
+	// As in verifySkipping, never trust a provider-supplied VoterSet: it
+	// must be reconstructed from next's own header rather than taken on
+	// faith from whoever served it.
+	next.VoterSet = types.SelectVoter(next.ValidatorSet, proofHashFromHeader(next.SignedHeader), c.voterParams)
+
+	return next.VoterSet.VerifyCommitLightTrusting(c.chainID, next.Commit, c.trustLevelOrDefault())
+}
+
+func (c *Client) trustLevelOrDefault() tmmath.Fraction {
+	if c.trustLevel.Numerator == 0 {
+		return DefaultTrustLevel
+	}
+	return c.trustLevel
+}
+
+// verifySkipping attempts to verify untrustedBlock directly against
+// trustedBlock using +trustLevel of the trusted voter set. If that isn't
+// possible it bisects the height range in half and recurses on each half,
+// reconstructing the voter set for every intermediate height it visits via
+// types.SelectVoter, since Ostracon (unlike Tendermint) re-elects its voter
+// set every block. It returns the full list of light blocks it verified
+// along the way, in ascending height order, trustedBlock and
+// untrustedBlock included.
+func (c *Client) verifySkipping(
+	ctx context.Context,
+	source provider.Provider,
+	trustedBlock *types.LightBlock,
+	untrustedBlock *types.LightBlock,
+	now time.Time,
+) ([]*types.LightBlock, error) {
+	if err := untrustedBlock.SignedHeader.ValidateBasic(c.chainID); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	if now.Before(untrustedBlock.Time) {
+		return nil, fmt.Errorf("new header has a time from the future: %v (now: %v)", untrustedBlock.Time, now)
+	}
+
+	if err := verifyValidatorSetHash(untrustedBlock); err != nil {
+		return nil, err
+	}
+
+	// Never trust a provider-supplied VoterSet: Ostracon elects a fresh
+	// voter set every block from the header's own proof hash, so it must be
+	// reconstructed from data the client can verify (the header we're
+	// checking), not taken on faith from whoever served it.
+	untrustedBlock.VoterSet = types.SelectVoter(untrustedBlock.ValidatorSet, proofHashFromHeader(untrustedBlock.SignedHeader), c.voterParams)
+
+	err := trustedBlock.VoterSet.VerifyCommitLightTrusting(c.chainID, untrustedBlock.Commit, c.trustLevelOrDefault())
+	switch err.(type) {
+	case nil:
+		return []*types.LightBlock{trustedBlock, untrustedBlock}, nil
+	case types.ErrNotEnoughVotingPowerSigned:
+		// fall through to bisection below
+	default:
+		return nil, err
+	}
+
+	if untrustedBlock.Height == trustedBlock.Height+1 {
+		return nil, fmt.Errorf("can't verify adjacent headers (%d -> %d) with %v of the trusted voter set signing: %w",
+			trustedBlock.Height, untrustedBlock.Height, c.trustLevelOrDefault(), err)
+	}
+
+	pivotHeight := (trustedBlock.Height + untrustedBlock.Height) / 2
+	pivotBlock, fetchErr := source.LightBlock(ctx, pivotHeight)
+	if fetchErr != nil {
+		return nil, fmt.Errorf("failed to fetch pivot light block at height %d: %w", pivotHeight, fetchErr)
+	}
+	if err := verifyValidatorSetHash(pivotBlock); err != nil {
+		return nil, fmt.Errorf("invalid pivot light block at height %d: %w", pivotHeight, err)
+	}
+	// same as above: recompute rather than trust the pivot's VoterSet.
+	pivotBlock.VoterSet = types.SelectVoter(pivotBlock.ValidatorSet, proofHashFromHeader(pivotBlock.SignedHeader), c.voterParams)
+
+	left, err := c.verifySkipping(ctx, source, trustedBlock, pivotBlock, now)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := c.verifySkipping(ctx, source, pivotBlock, untrustedBlock, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// pivotBlock is the last element of left and the first element of right
+	return append(left, right[1:]...), nil
+}
+
+// compareFirstHeaderWithWitnesses asks every witness for the header at the
+// trusted height and ensures it matches the primary's. If any witness
+// disagrees, the client refuses to start up; a witness that can't be
+// reached is dropped from the witness list.
+func (c *Client) compareFirstHeaderWithWitnesses(ctx context.Context, h *types.SignedHeader) error {
+	compareCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	agreeing := make([]provider.Provider, 0, len(c.witnesses))
+	for _, w := range c.witnesses {
+		lb, err := w.LightBlock(compareCtx, h.Height)
+		if err != nil {
+			// an unreachable witness is not grounds for failure; we simply
+			// drop it from the witness list used for cross-checks.
+			continue
+		}
+		if !bytes.Equal(lb.Hash(), h.Hash()) {
+			return fmt.Errorf("header %X does not match primary's header %X at height %d",
+				lb.Hash(), h.Hash(), h.Height)
+		}
+		agreeing = append(agreeing, w)
+	}
+	c.witnesses = agreeing
+	return nil
+}
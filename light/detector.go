@@ -0,0 +1,309 @@
+package light
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/line/ostracon/light/provider"
+	"github.com/line/ostracon/types"
+)
+
+// detectDivergence cross-checks targetBlock, which the client just accepted
+// from the primary, against every witness. trace is the sequence of light
+// blocks (trustedBlock..targetBlock) the client itself verified to get
+// there. A witness that disagrees with the trace means either it or the
+// primary is misbehaving: examineConflictingHeaderAgainstTrace pins down
+// exactly where the two views first diverge, and the client reports
+// LightClientAttackEvidence for that divergence to both of them, drops the
+// misbehaving witness, and returns a typed ErrLightClientAttack to the
+// caller.
+func (c *Client) detectDivergence(
+	ctx context.Context,
+	trace []*types.LightBlock,
+	targetBlock *types.LightBlock,
+	now time.Time,
+) error {
+	var (
+		agreeing = make([]provider.Provider, 0, len(c.witnesses))
+		firstErr error
+	)
+
+	for _, w := range c.witnesses {
+		agreedTrace, conflicting, err := c.examineConflictingHeaderAgainstTrace(trace, targetBlock, w, now)
+		if errors.Is(err, provider.ErrHeightTooHigh) {
+			// The witness doesn't have targetBlock's height yet. This could
+			// be a forward-lunatic attack: a malicious primary time-warping
+			// ahead of the real chain. Give the witness a chance to catch up
+			// before deciding either way.
+			if attackErr := c.examineForwardLunaticCandidate(ctx, w, targetBlock, now); attackErr != nil {
+				if firstErr == nil {
+					firstErr = attackErr
+				}
+				// the attack is the primary's; the witness that exposed it
+				// stays in the witness list.
+				agreeing = append(agreeing, w)
+				continue
+			}
+			agreeing = append(agreeing, w)
+			continue
+		}
+		if err != nil {
+			// an unreachable witness isn't conclusive evidence of an attack
+			// on its own.
+			agreeing = append(agreeing, w)
+			continue
+		}
+
+		if conflicting == nil {
+			agreeing = append(agreeing, w)
+			continue
+		}
+
+		attackErr := c.reportAttack(ctx, w, trace[0].Height, agreedTrace, conflicting, targetBlock)
+		if firstErr == nil {
+			firstErr = attackErr
+		}
+	}
+
+	c.witnesses = agreeing
+
+	return firstErr
+}
+
+// examineConflictingHeaderAgainstTrace walks the verified trace (from the
+// trusted height forward to targetBlock) against source's view of the same
+// chain, to find the first height at which the two disagree. The binary
+// search operates on the actual height range, not on indices into trace: a
+// successful skipping-verification run may only have visited a handful of
+// waypoints (e.g. trustedBlock and targetBlock alone, if the first one
+// satisfied the trust level directly), and Ostracon's per-block voter
+// rotation means the two views can diverge at any height in between, not
+// just at the ones the client happened to bisect to. Heights that trace
+// doesn't cover are fetched from the primary on demand, since trace is
+// only ever a subsequence of what the primary is willing to hand back for
+// this chain.
+//
+// It returns the prefix of light blocks source agrees with (trustedBlock
+// included), the conflicting light block source holds at the first height
+// it disagrees, and an error if trace is empty or if source, or the primary
+// for a height trace doesn't cover, could not be reached. A nil conflicting
+// block with a nil error means source agrees with the entire trace.
+func (c *Client) examineConflictingHeaderAgainstTrace(
+	trace []*types.LightBlock,
+	targetBlock *types.LightBlock,
+	source provider.Provider,
+	now time.Time,
+) ([]*types.LightBlock, *types.LightBlock, error) {
+	if len(trace) == 0 {
+		return nil, nil, errors.New("examineConflictingHeaderAgainstTrace: empty trace")
+	}
+
+	primaryAt := make(map[int64]*types.LightBlock, len(trace)+1)
+	for _, lb := range trace {
+		primaryAt[lb.Height] = lb
+	}
+	primaryAt[targetBlock.Height] = targetBlock
+
+	getPrimary := func(height int64) (*types.LightBlock, error) {
+		if lb, ok := primaryAt[height]; ok {
+			return lb, nil
+		}
+		lb, err := c.primary.LightBlock(context.Background(), height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch primary's light block at height %d: %w", height, err)
+		}
+		primaryAt[height] = lb
+		return lb, nil
+	}
+
+	sourceAt := make(map[int64]*types.LightBlock, len(trace)+1)
+	getSource := func(height int64) (*types.LightBlock, error) {
+		if lb, ok := sourceAt[height]; ok {
+			return lb, nil
+		}
+		lb, err := source.LightBlock(context.Background(), height)
+		if err != nil {
+			return nil, err
+		}
+		sourceAt[height] = lb
+		return lb, nil
+	}
+
+	lo, hi := trace[0].Height, targetBlock.Height
+
+	loBlock, err := getSource(lo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch source's light block at height %d: %w", lo, err)
+	}
+	if !bytes.Equal(loBlock.Hash(), trace[0].Hash()) {
+		// source disagrees even at the height we started verifying from;
+		// there is no agreed prefix to return.
+		return nil, loBlock, nil
+	}
+
+	hiBlock, err := getSource(hi)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch source's light block at height %d: %w", hi, err)
+	}
+	if bytes.Equal(hiBlock.Hash(), targetBlock.Hash()) {
+		// source agrees all the way up to targetBlock; nothing to report.
+		return trace, nil, nil
+	}
+
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		primaryMid, err := getPrimary(mid)
+		if err != nil {
+			return nil, nil, err
+		}
+		sourceMid, err := getSource(mid)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch source's light block at height %d: %w", mid, err)
+		}
+		if bytes.Equal(sourceMid.Hash(), primaryMid.Hash()) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	conflicting, err := getSource(hi)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch source's light block at height %d: %w", hi, err)
+	}
+
+	agreedTrace := make([]*types.LightBlock, 0, len(trace))
+	for _, lb := range trace {
+		if lb.Height <= lo {
+			agreedTrace = append(agreedTrace, lb)
+		}
+	}
+	if len(agreedTrace) == 0 || agreedTrace[len(agreedTrace)-1].Height != lo {
+		loPrimaryBlock, err := getPrimary(lo)
+		if err != nil {
+			return nil, nil, err
+		}
+		agreedTrace = append(agreedTrace, loPrimaryBlock)
+	}
+
+	return agreedTrace, conflicting, nil
+}
+
+// reportAttack builds LightClientAttackEvidence for the conflict found
+// between the client's own trace and the witness's conflicting view,
+// reports it to both the primary and the witness, and returns the typed
+// error the client surfaces to its caller.
+//
+// agreedTrace may be empty: examineConflictingHeaderAgainstTrace returns no
+// agreed prefix when the witness already disagrees at the very first
+// height it was asked about, i.e. it never shared any history with us at
+// all within the verified trace. rootHeight (the earliest height the
+// client verified) is used as the common height in that case, since it's
+// the best anchor available.
+func (c *Client) reportAttack(
+	ctx context.Context,
+	witness provider.Provider,
+	rootHeight int64,
+	agreedTrace []*types.LightBlock,
+	conflicting *types.LightBlock,
+	targetBlock *types.LightBlock,
+) error {
+	commonHeight := rootHeight
+	if len(agreedTrace) > 0 {
+		commonHeight = agreedTrace[len(agreedTrace)-1].Height
+	}
+
+	// The two sides disagree on which block was signed at conflicting's
+	// height. Whether that's equivocation (same voters, two different
+	// blocks) or a lunatic attack (the voters themselves were forged) comes
+	// down to whether the primary's own validator set at that height matches
+	// conflicting's (the voters of both are elected from it) -- if it does,
+	// the set that double-signed is punishable at the height it
+	// double-signed, not before.
+	if primaryAtConflict, err := c.primary.LightBlock(ctx, conflicting.Height); err == nil &&
+		bytes.Equal(primaryAtConflict.ValidatorSet.Hash(), conflicting.ValidatorSet.Hash()) {
+		commonHeight = conflicting.Height
+	}
+
+	evAgainstWitness := &types.LightClientAttackEvidence{
+		ConflictingBlock: conflicting,
+		CommonHeight:     commonHeight,
+	}
+	evAgainstPrimary := &types.LightClientAttackEvidence{
+		ConflictingBlock: targetBlock,
+		CommonHeight:     commonHeight,
+	}
+
+	if err := witness.ReportEvidence(ctx, evAgainstPrimary); err != nil {
+		c.logger.Error("failed to report evidence to witness", "err", err)
+	}
+	if err := c.primary.ReportEvidence(ctx, evAgainstWitness); err != nil {
+		c.logger.Error("failed to report evidence to primary", "err", err)
+	}
+
+	return ErrLightClientAttack{Evidence: evAgainstWitness, WitnessRemoved: true}
+}
+
+// examineForwardLunaticCandidate is called when a witness reports that
+// targetBlock's height is beyond its own tip. It polls the witness for up
+// to Client.maxBlockLag past the primary's header time to give a merely
+// slow witness a chance to catch up. If, at any poll up to that deadline,
+// the witness's latest block time has passed targetBlock's time while its
+// height is still behind, the primary must have forged a header with a
+// future timestamp -- a forward-lunatic attack -- and the witness's latest
+// block is used as the common height for the resulting evidence, which is
+// reported to both the primary and the witness.
+func (c *Client) examineForwardLunaticCandidate(
+	ctx context.Context,
+	witness provider.Provider,
+	targetBlock *types.LightBlock,
+	now time.Time,
+) error {
+	deadline := targetBlock.Time.Add(c.maxBlockLag)
+
+	var latest *types.LightBlock
+	for {
+		lb, err := witness.LightBlock(ctx, 0)
+		if err == nil {
+			latest = lb
+			if latest.Height >= targetBlock.Height || latest.Time.After(targetBlock.Time) {
+				break
+			}
+		}
+
+		if !now.Before(deadline) {
+			break
+		}
+		c.sleep(c.witnessPollInterval)
+		now = now.Add(c.witnessPollInterval)
+	}
+
+	if latest == nil || latest.Height >= targetBlock.Height {
+		// the witness either never responded or has since caught up -- not
+		// enough to call this an attack.
+		return nil
+	}
+
+	if !latest.Time.After(targetBlock.Time) {
+		// the witness is still behind in both height and time: it's just
+		// slow, not proof that the primary forged the future.
+		return nil
+	}
+
+	ev := &types.LightClientAttackEvidence{
+		ConflictingBlock: targetBlock,
+		CommonHeight:     latest.Height,
+	}
+
+	if err := c.primary.ReportEvidence(ctx, ev); err != nil {
+		c.logger.Error("failed to report forward-lunatic evidence to primary", "err", err)
+	}
+	if err := witness.ReportEvidence(ctx, ev); err != nil {
+		c.logger.Error("failed to report forward-lunatic evidence to witness", "err", err)
+	}
+
+	return ErrLightClientAttack{Evidence: ev}
+}
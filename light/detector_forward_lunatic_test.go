@@ -0,0 +1,100 @@
+package light_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/line/ostracon/light"
+	"github.com/line/ostracon/light/provider"
+	mockp "github.com/line/ostracon/light/provider/mock"
+	"github.com/line/ostracon/types"
+)
+
+// steppingWitness simulates a witness that is behind the primary's tip for
+// its first catchUpAfter calls to LightBlock(ctx, 0), then reports caughtUp
+// for every call after that -- letting tests drive examineForwardLunatic-
+// Candidate's retry loop deterministically.
+type steppingWitness struct {
+	calls        int
+	behind       *types.LightBlock
+	caughtUp     *types.LightBlock
+	catchUpAfter int
+}
+
+func (w *steppingWitness) LightBlock(_ context.Context, _ int64) (*types.LightBlock, error) {
+	w.calls++
+	if w.calls > w.catchUpAfter {
+		return w.caughtUp, nil
+	}
+	return w.behind, nil
+}
+
+func (w *steppingWitness) ReportEvidence(_ context.Context, _ types.Evidence) error {
+	return nil
+}
+
+func lightBlockAt(height int64, t time.Time) *types.LightBlock {
+	header := &types.Header{
+		ChainID: chainID,
+		Height:  height,
+		Time:    t,
+	}
+	return &types.LightBlock{SignedHeader: &types.SignedHeader{Header: header}}
+}
+
+// TestExamineForwardLunaticCandidate_WitnessCatchesUp exercises the case
+// where a witness is merely slow: it's behind in height for a couple of
+// polls but eventually reports a height at or past targetBlock's, so no
+// attack should be reported.
+func TestExamineForwardLunaticCandidate_WitnessCatchesUp(t *testing.T) {
+	primary := mockp.New(genMockNode(chainID, 1, 5, 2, bTime))
+	c := newTestClient(t, primary, 1)
+
+	targetBlock := lightBlockAt(10, bTime.Add(10*time.Minute))
+	w := &steppingWitness{
+		behind:       lightBlockAt(5, bTime.Add(5*time.Minute)),
+		caughtUp:     lightBlockAt(10, bTime.Add(10*time.Minute)),
+		catchUpAfter: 3,
+	}
+
+	var slept []time.Duration
+	light.SetPollingForTest(c, time.Second, func(d time.Duration) { slept = append(slept, d) })
+
+	err := light.ExamineForwardLunaticCandidateForTest(c, ctx, w, targetBlock, bTime)
+	require.NoError(t, err)
+	assert.NotEmpty(t, slept, "the retry loop should have polled the witness more than once")
+}
+
+// TestExamineForwardLunaticCandidate_Attack exercises the case where the
+// witness never catches up in height within the lag budget, but its clock
+// eventually passes targetBlock's time -- proof that the primary forged a
+// header with a future timestamp.
+func TestExamineForwardLunaticCandidate_Attack(t *testing.T) {
+	primary := mockp.New(genMockNode(chainID, 1, 5, 2, bTime))
+	c := newTestClient(t, primary, 1)
+
+	targetBlock := lightBlockAt(10, bTime.Add(10*time.Minute))
+	laggingWitness := lightBlockAt(5, bTime.Add(20*time.Minute))
+	w := &steppingWitness{
+		behind:       lightBlockAt(5, bTime.Add(1*time.Minute)),
+		caughtUp:     laggingWitness,
+		catchUpAfter: 3,
+	}
+
+	var slept []time.Duration
+	light.SetPollingForTest(c, time.Second, func(d time.Duration) { slept = append(slept, d) })
+	light.MaxBlockLag(10 * time.Second)(c)
+
+	err := light.ExamineForwardLunaticCandidateForTest(c, ctx, w, targetBlock, bTime)
+	require.Error(t, err)
+
+	attackErr, ok := err.(light.ErrLightClientAttack)
+	require.True(t, ok)
+	assert.Equal(t, int64(5), attackErr.Evidence.CommonHeight)
+	assert.True(t, primary.HasEvidence(attackErr.Evidence))
+	assert.NotEmpty(t, slept, "the retry loop should have polled the witness before giving up")
+}
@@ -0,0 +1,189 @@
+package light_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/line/ostracon/libs/log"
+	"github.com/line/ostracon/light"
+	"github.com/line/ostracon/light/provider"
+	mockp "github.com/line/ostracon/light/provider/mock"
+	dbs "github.com/line/ostracon/light/store/db"
+	"github.com/line/ostracon/types"
+)
+
+// newTestClient builds a client against primary whose trusted store already
+// holds the light block at firstHeight, so examineConflictingHeaderAgainstTrace
+// can be exercised directly without re-deriving a trace through
+// VerifyLightBlockAtHeight.
+func newTestClient(t *testing.T, primary provider.Provider, firstHeight int64) *light.Client {
+	t.Helper()
+	firstBlock, err := primary.LightBlock(ctx, firstHeight)
+	require.NoError(t, err)
+
+	c, err := light.NewClient(
+		ctx,
+		chainID,
+		light.TrustOptions{
+			Height: firstHeight,
+			Hash:   firstBlock.Hash(),
+			Period: 4 * time.Hour,
+		},
+		primary,
+		nil,
+		dbs.New(dbm.NewMemDB(), chainID),
+		types.DefaultVoterParams(),
+		light.Logger(log.TestingLogger()),
+		light.MaxRetryAttempts(1),
+	)
+	require.NoError(t, err)
+	return c
+}
+
+// TestExamineConflictingHeaderAgainstTrace_Equivocation reproduces a trace
+// where the source signs the very same validators/voters at the divergence
+// height, but over a different block -- a classic equivocation attack.
+func TestExamineConflictingHeaderAgainstTrace_Equivocation(t *testing.T) {
+	latestHeight := int64(10)
+	divergenceHeight := int64(6)
+
+	// +2 so that primaryVals[h+1] exists for h == latestHeight
+	primaryHeaders, primaryVals, primaryVoters, chainKeys := genMockNodeWithKeys(chainID, latestHeight+2, 5, 2, bTime)
+	primary := mockp.New(chainID, primaryHeaders, primaryVals, primaryVoters)
+
+	sourceHeaders := make(map[int64]*types.SignedHeader, latestHeight)
+	sourceVals := make(map[int64]*types.ValidatorSet, latestHeight)
+	sourceVoters := make(map[int64]*types.VoterSet, latestHeight)
+	for h := int64(1); h <= latestHeight; h++ {
+		sourceVals[h] = primaryVals[h]
+		if h < divergenceHeight {
+			sourceHeaders[h] = primaryHeaders[h]
+			sourceVoters[h] = primaryVoters[h]
+			continue
+		}
+		sourceHeaders[h] = chainKeys[h].GenSignedHeader(chainID, h, bTime.Add(time.Duration(h)*time.Minute),
+			[]types.Tx{[]byte("equivocate")}, primaryVals[h], primaryVals[h+1], hash("app_hash"),
+			hash("cons_hash"), hash("results_hash"), 0, len(chainKeys[h]), types.DefaultVoterParams())
+		sourceVoters[h] = types.SelectVoter(sourceVals[h], proofHash(sourceHeaders[h]), types.DefaultVoterParams())
+	}
+	source := mockp.New(chainID, sourceHeaders, sourceVals, sourceVoters)
+
+	c := newTestClient(t, primary, 1)
+
+	trace := make([]*types.LightBlock, 0, latestHeight)
+	for h := int64(1); h <= latestHeight; h++ {
+		lb, err := primary.LightBlock(ctx, h)
+		require.NoError(t, err)
+		trace = append(trace, lb)
+	}
+
+	agreed, conflicting, err := light.ExamineConflictingHeaderAgainstTraceForTest(c, trace, trace[len(trace)-1], source, bTime.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, conflicting)
+	assert.Equal(t, divergenceHeight, conflicting.Height)
+	assert.Equal(t, divergenceHeight-1, agreed[len(agreed)-1].Height)
+}
+
+// TestExamineConflictingHeaderAgainstTrace_Lunatic reproduces a trace where
+// the source has forged a new validator set from the divergence height
+// onwards -- a lunatic attack, distinguishable from equivocation by the
+// conflicting block's validator set no longer matching the trusted trace.
+func TestExamineConflictingHeaderAgainstTrace_Lunatic(t *testing.T) {
+	latestHeight := int64(10)
+	divergenceHeight := int64(6)
+
+	primaryHeaders, primaryVals, primaryVoters, chainKeys := genMockNodeWithKeys(chainID, latestHeight, 5, 2, bTime)
+	primary := mockp.New(chainID, primaryHeaders, primaryVals, primaryVoters)
+
+	forgedKeys := chainKeys[divergenceHeight].ChangeKeys(3)
+	forgedVals := forgedKeys.ToValidators(2, 0)
+
+	sourceHeaders := make(map[int64]*types.SignedHeader, latestHeight)
+	sourceVals := make(map[int64]*types.ValidatorSet, latestHeight)
+	sourceVoters := make(map[int64]*types.VoterSet, latestHeight)
+	for h := int64(1); h <= latestHeight; h++ {
+		if h < divergenceHeight {
+			sourceHeaders[h] = primaryHeaders[h]
+			sourceVals[h] = primaryVals[h]
+			sourceVoters[h] = primaryVoters[h]
+			continue
+		}
+		sourceHeaders[h] = forgedKeys.GenSignedHeader(chainID, h, bTime.Add(time.Duration(h)*time.Minute), nil,
+			forgedVals, forgedVals, hash("app_hash"), hash("cons_hash"), hash("results_hash"),
+			0, len(forgedKeys), types.DefaultVoterParams())
+		sourceVals[h] = forgedVals
+		sourceVoters[h] = types.SelectVoter(forgedVals, proofHash(sourceHeaders[h]), types.DefaultVoterParams())
+	}
+	source := mockp.New(chainID, sourceHeaders, sourceVals, sourceVoters)
+
+	c := newTestClient(t, primary, 1)
+
+	trace := make([]*types.LightBlock, 0, latestHeight)
+	for h := int64(1); h <= latestHeight; h++ {
+		lb, err := primary.LightBlock(ctx, h)
+		require.NoError(t, err)
+		trace = append(trace, lb)
+	}
+
+	agreed, conflicting, err := light.ExamineConflictingHeaderAgainstTraceForTest(c, trace, trace[len(trace)-1], source, bTime.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, conflicting)
+	assert.Equal(t, divergenceHeight, conflicting.Height)
+	assert.NotEqual(t, primaryVals[divergenceHeight].Hash(), conflicting.ValidatorSet.Hash())
+	assert.Equal(t, divergenceHeight-1, agreed[len(agreed)-1].Height)
+}
+
+// TestExamineConflictingHeaderAgainstTrace_SparseTrace exercises the case
+// skipping verification actually produces: a trace containing only the
+// handful of heights the bisection visited (here, just the endpoints),
+// rather than every height in between. The binary search must still land
+// on the true divergence height by fetching intermediate heights from the
+// primary as needed, not just snap to the nearest waypoint already in
+// trace.
+func TestExamineConflictingHeaderAgainstTrace_SparseTrace(t *testing.T) {
+	latestHeight := int64(10)
+	divergenceHeight := int64(6)
+
+	// +2 so that primaryVals[h+1] exists for h == latestHeight
+	primaryHeaders, primaryVals, primaryVoters, chainKeys := genMockNodeWithKeys(chainID, latestHeight+2, 5, 2, bTime)
+	primary := mockp.New(chainID, primaryHeaders, primaryVals, primaryVoters)
+
+	sourceHeaders := make(map[int64]*types.SignedHeader, latestHeight)
+	sourceVals := make(map[int64]*types.ValidatorSet, latestHeight)
+	sourceVoters := make(map[int64]*types.VoterSet, latestHeight)
+	for h := int64(1); h <= latestHeight; h++ {
+		sourceVals[h] = primaryVals[h]
+		if h < divergenceHeight {
+			sourceHeaders[h] = primaryHeaders[h]
+			sourceVoters[h] = primaryVoters[h]
+			continue
+		}
+		sourceHeaders[h] = chainKeys[h].GenSignedHeader(chainID, h, bTime.Add(time.Duration(h)*time.Minute),
+			[]types.Tx{[]byte("equivocate")}, primaryVals[h], primaryVals[h+1], hash("app_hash"),
+			hash("cons_hash"), hash("results_hash"), 0, len(chainKeys[h]), types.DefaultVoterParams())
+		sourceVoters[h] = types.SelectVoter(sourceVals[h], proofHash(sourceHeaders[h]), types.DefaultVoterParams())
+	}
+	source := mockp.New(chainID, sourceHeaders, sourceVals, sourceVoters)
+
+	c := newTestClient(t, primary, 1)
+
+	firstBlock, err := primary.LightBlock(ctx, 1)
+	require.NoError(t, err)
+	lastBlock, err := primary.LightBlock(ctx, latestHeight)
+	require.NoError(t, err)
+
+	// A sparse trace, as verifySkipping would produce when trustLevel is
+	// satisfied directly between the endpoints with no bisection at all.
+	sparseTrace := []*types.LightBlock{firstBlock, lastBlock}
+
+	agreed, conflicting, err := light.ExamineConflictingHeaderAgainstTraceForTest(c, sparseTrace, lastBlock, source, bTime.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, conflicting)
+	assert.Equal(t, divergenceHeight, conflicting.Height, "divergence must resolve to the true height, not a trace waypoint")
+	assert.Equal(t, divergenceHeight-1, agreed[len(agreed)-1].Height)
+}
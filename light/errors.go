@@ -0,0 +1,30 @@
+package light
+
+import (
+	"fmt"
+
+	"github.com/line/ostracon/types"
+)
+
+// ErrLightClientAttack is returned by the client to the caller when it has
+// confirmed that either the primary or one of its witnesses is conducting a
+// light client attack. Evidence of the attack has already been reported to
+// both the primary and the witness involved by the time this error is
+// returned.
+type ErrLightClientAttack struct {
+	Evidence *types.LightClientAttackEvidence
+	// WitnessRemoved is true when the attack was attributed to a witness,
+	// which has since been dropped from the client's witness list.
+	WitnessRemoved bool
+}
+
+func (e ErrLightClientAttack) Error() string {
+	if !e.WitnessRemoved {
+		return fmt.Sprintf(
+			"primary's header %X at height %d is ahead of the witness's chain: attempted light client attack detected (common height %d)",
+			e.Evidence.ConflictingBlock.Hash(), e.Evidence.ConflictingBlock.Height, e.Evidence.CommonHeight)
+	}
+	return fmt.Sprintf(
+		"header %X does not match primary's header at height %d: attempted light client attack detected (common height %d)",
+		e.Evidence.ConflictingBlock.Hash(), e.Evidence.ConflictingBlock.Height, e.Evidence.CommonHeight)
+}
@@ -0,0 +1,42 @@
+package light
+
+import (
+	"context"
+	"time"
+
+	"github.com/line/ostracon/light/provider"
+	"github.com/line/ostracon/types"
+)
+
+// ExamineConflictingHeaderAgainstTraceForTest exposes
+// (*Client).examineConflictingHeaderAgainstTrace to tests in package
+// light_test.
+func ExamineConflictingHeaderAgainstTraceForTest(
+	c *Client,
+	trace []*types.LightBlock,
+	targetBlock *types.LightBlock,
+	source provider.Provider,
+	now time.Time,
+) ([]*types.LightBlock, *types.LightBlock, error) {
+	return c.examineConflictingHeaderAgainstTrace(trace, targetBlock, source, now)
+}
+
+// ExamineForwardLunaticCandidateForTest exposes
+// (*Client).examineForwardLunaticCandidate to tests in package light_test.
+func ExamineForwardLunaticCandidateForTest(
+	c *Client,
+	ctx context.Context,
+	witness provider.Provider,
+	targetBlock *types.LightBlock,
+	now time.Time,
+) error {
+	return c.examineForwardLunaticCandidate(ctx, witness, targetBlock, now)
+}
+
+// SetPollingForTest overrides the witness poll interval and sleep function
+// a Client uses in examineForwardLunaticCandidate, so tests can drive its
+// retry loop without waiting on the wall clock.
+func SetPollingForTest(c *Client, interval time.Duration, sleep func(time.Duration)) {
+	c.witnessPollInterval = interval
+	c.sleep = sleep
+}
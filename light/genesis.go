@@ -0,0 +1,27 @@
+package light
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/line/ostracon/types"
+)
+
+// TrustOptionsFromGenesis derives TrustOptions from a GenesisDoc's embedded
+// weak-subjectivity checkpoint (InitialHeader/InitialVoterSet), so that a
+// light client or state-syncing node bootstrapping mid-chain can trust
+// InitialHeight-1 without needing an out-of-band trust hash. period is the
+// trusting period to use; it isn't part of the genesis document since it
+// depends on the chain's unbonding time, which can change after genesis.
+func TrustOptionsFromGenesis(genDoc types.GenesisDoc, period time.Duration) (TrustOptions, error) {
+	if genDoc.InitialHeader == nil || genDoc.InitialVoterSet == nil {
+		return TrustOptions{}, fmt.Errorf(
+			"genesis doc for chain %q has no initial_header/initial_voter_set checkpoint to trust", genDoc.ChainID)
+	}
+
+	return TrustOptions{
+		Period: period,
+		Height: genDoc.InitialHeader.Height,
+		Hash:   genDoc.InitialHeader.Hash(),
+	}, nil
+}
@@ -0,0 +1,210 @@
+package light_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+
+	"github.com/line/ostracon/light/provider"
+	"github.com/line/ostracon/types"
+)
+
+var (
+	ctx      = context.Background()
+	chainID  = "test-light-client"
+	bTime, _ = time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+)
+
+// hash produces a deterministic, arbitrary-looking hash from a string, used
+// to stand in for app/results/consensus hashes in generated test headers.
+func hash(s string) []byte {
+	return tmhash.Sum([]byte(s))
+}
+
+// proofHash mirrors the per-height proof hash that production code (see
+// light.Client) derives from a header in order to select that height's
+// voter set via types.SelectVoter. Real proof hashes come from the block
+// proposer's VRF proof; since this mock harness doesn't run VRF signing,
+// the header's own hash is used as a stand-in source of per-height entropy.
+func proofHash(sh *types.SignedHeader) []byte {
+	return sh.Header.Hash()
+}
+
+// keys is a set of private validator keys used to build mock chains with
+// full control over who signs what.
+type keys []crypto.PrivKey
+
+func genPrivKeys(n int) keys {
+	res := make(keys, n)
+	for i := 0; i < n; i++ {
+		res[i] = ed25519.GenPrivKey()
+	}
+	return res
+}
+
+// ChangeKeys returns a new key set where the first n keys have been
+// replaced with fresh ones and the rest are kept, to simulate a validator
+// set that has partially turned over (e.g. for a lunatic attack where a
+// minority of the original validators colludes with new ones).
+func (ks keys) ChangeKeys(n int) keys {
+	out := make(keys, len(ks))
+	copy(out, ks)
+	fresh := genPrivKeys(n)
+	for i := 0; i < n && i < len(out); i++ {
+		out[i] = fresh[i]
+	}
+	return out
+}
+
+// ToValidators builds a ValidatorSet where every key is given the same
+// voting power, plus an increment per index to avoid ties.
+func (ks keys) ToValidators(power, inc int64) *types.ValidatorSet {
+	vals := make([]*types.Validator, len(ks))
+	for i, k := range ks {
+		vals[i] = types.NewValidator(k.PubKey(), power+int64(i)*inc)
+	}
+	return types.NewValidatorSet(vals)
+}
+
+// GenSignedHeader builds a SignedHeader at the given height, committed to
+// by the voters at indices [first, last) of the voter set elected from
+// valSet, each signing with its key from ks.
+func (ks keys) GenSignedHeader(
+	chainID string,
+	height int64,
+	t time.Time,
+	txs types.Txs,
+	valSet, nextValSet *types.ValidatorSet,
+	appHash, consHash, resultsHash []byte,
+	first, last int,
+	voterParams *types.VoterParams,
+) *types.SignedHeader {
+	voterSet := types.SelectVoter(valSet, tmhash.Sum(appHash), voterParams)
+
+	header := &types.Header{
+		ChainID:            chainID,
+		Height:             height,
+		Time:               t,
+		ValidatorsHash:     valSet.Hash(),
+		NextValidatorsHash: nextValSet.Hash(),
+		DataHash:           txs.Hash(),
+		AppHash:            appHash,
+		ConsensusHash:      consHash,
+		LastResultsHash:    resultsHash,
+	}
+
+	commit := ks.signHeader(header, voterSet, first, last)
+
+	return &types.SignedHeader{
+		Header: header,
+		Commit: commit,
+	}
+}
+
+// signHeader produces a commit over header signed by voterSet[first:last].
+func (ks keys) signHeader(header *types.Header, voterSet *types.VoterSet, first, last int) *types.Commit {
+	blockID := types.BlockID{Hash: header.Hash()}
+
+	sigs := make([]types.CommitSig, len(voterSet.Voters))
+	for i := range sigs {
+		sigs[i] = types.NewCommitSigAbsent()
+	}
+
+	for i := first; i < last && i < len(voterSet.Voters); i++ {
+		voter := voterSet.Voters[i]
+
+		var priv crypto.PrivKey
+		for _, k := range ks {
+			if k.PubKey().Address().String() == voter.Address.String() {
+				priv = k
+				break
+			}
+		}
+		if priv == nil {
+			continue
+		}
+
+		vote := &types.Vote{
+			Type:             types.PrecommitType,
+			Height:           header.Height,
+			Round:            0,
+			BlockID:          blockID,
+			Timestamp:        header.Time,
+			ValidatorAddress: voter.Address,
+			ValidatorIndex:   i,
+		}
+		sig, err := priv.Sign(vote.SignBytes(header.ChainID))
+		if err != nil {
+			panic(err)
+		}
+		vote.Signature = sig
+		sigs[i] = types.NewCommitSigForBlock(vote.Signature, voter.Address, vote.Timestamp)
+	}
+
+	return types.NewCommit(header.Height, 0, blockID, sigs)
+}
+
+// genMockNode builds a single-chain mock node: a chain of `height` blocks
+// signed by a stable set of `valSize` validators, each with the given base
+// voting power.
+func genMockNode(
+	chainID string,
+	height int64,
+	valSize int,
+	votingPower int64,
+	t time.Time,
+) (string, map[int64]*types.SignedHeader, map[int64]*types.ValidatorSet, map[int64]*types.VoterSet) {
+	headers, vals, voters, _ := genMockNodeWithKeys(chainID, height, valSize, votingPower, t)
+	return chainID, headers, vals, voters
+}
+
+// genMockNodeWithKeys is genMockNode but also returns the keys used to sign
+// each height, so callers can fork the chain (e.g. to simulate an attack)
+// from any point onwards.
+func genMockNodeWithKeys(
+	chainID string,
+	height int64,
+	valSize int,
+	votingPower int64,
+	t time.Time,
+) (map[int64]*types.SignedHeader, map[int64]*types.ValidatorSet, map[int64]*types.VoterSet, map[int64]keys) {
+	headers := make(map[int64]*types.SignedHeader, height)
+	vals := make(map[int64]*types.ValidatorSet, height)
+	voters := make(map[int64]*types.VoterSet, height)
+	chainKeys := make(map[int64]keys, height)
+
+	valKeys := genPrivKeys(valSize)
+	valSet := valKeys.ToValidators(votingPower, 0)
+
+	for h := int64(1); h <= height+2; h++ {
+		chainKeys[h] = valKeys
+	}
+
+	for h := int64(1); h <= height; h++ {
+		header := valKeys.GenSignedHeader(chainID, h, t.Add(time.Duration(h)*time.Minute), nil,
+			valSet, valSet, hash("app_hash"), hash("cons_hash"), hash("results_hash"),
+			0, len(valKeys), types.DefaultVoterParams())
+		headers[h] = header
+		vals[h] = valSet
+		voters[h] = types.SelectVoter(valSet, proofHash(header), types.DefaultVoterParams())
+	}
+
+	return headers, vals, voters, chainKeys
+}
+
+// deadNode is a provider that never responds, used to simulate unreachable
+// witnesses.
+var deadNode provider.Provider = deadProvider{}
+
+type deadProvider struct{}
+
+func (deadProvider) LightBlock(_ context.Context, _ int64) (*types.LightBlock, error) {
+	return nil, provider.ErrNoResponse
+}
+
+func (deadProvider) ReportEvidence(_ context.Context, _ types.Evidence) error {
+	return provider.ErrNoResponse
+}
@@ -0,0 +1,22 @@
+package provider
+
+import "errors"
+
+var (
+	// ErrNoResponse means the provider didn't respond to the request.
+	ErrNoResponse = errors.New("no response from provider")
+
+	// ErrLightBlockNotFound is returned when a provider doesn't have the
+	// requested header.
+	ErrLightBlockNotFound = errors.New("light block not found")
+
+	// ErrHeightTooHigh is returned when the requested height is higher than
+	// the height of the provider's current tip. This can happen when a
+	// witness has fallen behind the primary, for example, and is asked for
+	// a light block at a height the primary has already produced.
+	ErrHeightTooHigh = errors.New("height requested is too high")
+
+	// ErrBadLightBlock is returned when a provider returns an invalid
+	// light block.
+	ErrBadLightBlock = errors.New("bad light block")
+)
@@ -0,0 +1,90 @@
+package mock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/line/ostracon/light/provider"
+	"github.com/line/ostracon/types"
+)
+
+// mock implements the provider.Provider interface directly off of maps of
+// headers, validator sets and voter sets keyed by height. It is used
+// exclusively in tests to simulate a full node that the light client
+// connects to, and additionally records any evidence reported to it so
+// that tests can assert it was sent.
+type mock struct {
+	chainID string
+
+	mtx       sync.Mutex
+	headers   map[int64]*types.SignedHeader
+	vals      map[int64]*types.ValidatorSet
+	voters    map[int64]*types.VoterSet
+	evidence  []types.Evidence
+	maxHeight int64
+}
+
+// New creates a mock provider with the given set of headers, validator sets
+// and voter sets, keyed by height.
+func New(
+	chainID string,
+	headers map[int64]*types.SignedHeader,
+	vals map[int64]*types.ValidatorSet,
+	voters map[int64]*types.VoterSet,
+) provider.Provider {
+	var maxHeight int64
+	for height := range headers {
+		if height > maxHeight {
+			maxHeight = height
+		}
+	}
+	return &mock{
+		chainID:   chainID,
+		headers:   headers,
+		vals:      vals,
+		voters:    voters,
+		maxHeight: maxHeight,
+	}
+}
+
+func (m *mock) LightBlock(_ context.Context, height int64) (*types.LightBlock, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if height == 0 {
+		height = m.maxHeight
+	} else if height > m.maxHeight {
+		return nil, provider.ErrHeightTooHigh
+	}
+
+	sh, ok := m.headers[height]
+	if !ok {
+		return nil, provider.ErrLightBlockNotFound
+	}
+
+	return &types.LightBlock{
+		SignedHeader: sh,
+		ValidatorSet: m.vals[height],
+		VoterSet:     m.voters[height],
+	}, nil
+}
+
+func (m *mock) ReportEvidence(_ context.Context, ev types.Evidence) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.evidence = append(m.evidence, ev)
+	return nil
+}
+
+// HasEvidence returns true if the given evidence was previously reported to
+// this provider via ReportEvidence.
+func (m *mock) HasEvidence(ev types.Evidence) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	for _, e := range m.evidence {
+		if e.Hash() != nil && ev.Hash() != nil && string(e.Hash()) == string(ev.Hash()) {
+			return true
+		}
+	}
+	return false
+}
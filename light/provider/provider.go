@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/line/ostracon/types"
+)
+
+// Provider defines an interface for a light client provider, which
+// represents a full node it communicates with.
+type Provider interface {
+	// LightBlock returns the LightBlock that corresponds to the given
+	// height.
+	//
+	// 0 height returns the latest LightBlock.
+	//
+	// Height must be >= 0.
+	//
+	// If the provider fails to return the LightBlock for the given height,
+	// it returns an error describing why, which will be one of
+	// ErrNoResponse, ErrLightBlockNotFound, ErrHeightTooHigh, or
+	// ErrBadLightBlock.
+	LightBlock(ctx context.Context, height int64) (*types.LightBlock, error)
+
+	// ReportEvidence reports an evidence of misbehavior to the provider,
+	// so that it can act on it (e.g. gossip it to other full nodes,
+	// punish the misbehaving validators).
+	ReportEvidence(ctx context.Context, ev types.Evidence) error
+}
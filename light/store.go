@@ -0,0 +1,37 @@
+package light
+
+import (
+	"github.com/line/ostracon/types"
+)
+
+// Store is anything that can persistently store light blocks.
+type Store interface {
+	// SaveLightBlock saves a LightBlock.
+	SaveLightBlock(lb *types.LightBlock) error
+
+	// DeleteLightBlock deletes the LightBlock at the given height.
+	DeleteLightBlock(height int64) error
+
+	// LightBlock returns the LightBlock at the given height, or
+	// ErrLightBlockNotFound if the store does not have it.
+	LightBlock(height int64) (*types.LightBlock, error)
+
+	// LastLightBlockHeight returns the last (newest) known light block
+	// height, or -1 if no light blocks exist.
+	LastLightBlockHeight() (int64, error)
+
+	// FirstLightBlockHeight returns the first (oldest) known light block
+	// height, or -1 if no light blocks exist.
+	FirstLightBlockHeight() (int64, error)
+
+	// LightBlockBefore returns the LightBlock before the given height, if
+	// it exists.
+	LightBlockBefore(height int64) (*types.LightBlock, error)
+
+	// Prune prunes the store down to the given number of light blocks,
+	// discarding the oldest ones first.
+	Prune(size uint16) error
+
+	// Size returns the number of light blocks currently in the store.
+	Size() uint16
+}
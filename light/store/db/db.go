@@ -0,0 +1,216 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/line/ostracon/light"
+	"github.com/line/ostracon/light/provider"
+	"github.com/line/ostracon/types"
+)
+
+var sizeKey = []byte("size")
+
+// dbs is a Store that wraps a DB to persist light blocks.
+type dbs struct {
+	db     dbm.DB
+	prefix string
+
+	mtx  sync.RWMutex
+	size uint16
+}
+
+// New creates a new light block store using the given database and chain
+// ID, used to prefix keys so that multiple chains can share the same
+// underlying DB.
+func New(db dbm.DB, chainID string) light.Store {
+	store := &dbs{db: db, prefix: chainID}
+	bz, err := db.Get(store.sizeKey())
+	if err == nil && len(bz) > 0 {
+		var size uint16
+		if err := json.Unmarshal(bz, &size); err == nil {
+			store.size = size
+		}
+	}
+	return store
+}
+
+func (s *dbs) SaveLightBlock(lb *types.LightBlock) error {
+	if lb == nil || lb.SignedHeader == nil {
+		return fmt.Errorf("light block or its signed header cannot be nil")
+	}
+
+	bz, err := json.Marshal(lb)
+	if err != nil {
+		return fmt.Errorf("marshaling LightBlock: %w", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	existed, err := s.db.Has(s.lbKey(lb.Height))
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Set(s.lbKey(lb.Height), bz); err != nil {
+		return err
+	}
+
+	if existed {
+		return nil
+	}
+	s.size++
+	return s.db.Set(s.sizeKey(), mustJSON(s.size))
+}
+
+func (s *dbs) DeleteLightBlock(height int64) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if _, err := s.lightBlock(height); err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(s.lbKey(height)); err != nil {
+		return err
+	}
+
+	if s.size > 0 {
+		s.size--
+	}
+	return s.db.Set(s.sizeKey(), mustJSON(s.size))
+}
+
+func (s *dbs) LightBlock(height int64) (*types.LightBlock, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.lightBlock(height)
+}
+
+func (s *dbs) lightBlock(height int64) (*types.LightBlock, error) {
+	bz, err := s.db.Get(s.lbKey(height))
+	if err != nil {
+		return nil, err
+	}
+	if len(bz) == 0 {
+		return nil, provider.ErrLightBlockNotFound
+	}
+
+	var lb types.LightBlock
+	if err := json.Unmarshal(bz, &lb); err != nil {
+		return nil, fmt.Errorf("unmarshaling LightBlock: %w", err)
+	}
+	return &lb, nil
+}
+
+func (s *dbs) LastLightBlockHeight() (int64, error) {
+	return s.extremeHeight(true)
+}
+
+func (s *dbs) FirstLightBlockHeight() (int64, error) {
+	return s.extremeHeight(false)
+}
+
+func (s *dbs) extremeHeight(last bool) (int64, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var (
+		it  dbm.Iterator
+		err error
+	)
+	if last {
+		it, err = s.db.ReverseIterator(s.lbKey(1), s.lbKey(1<<62))
+	} else {
+		it, err = s.db.Iterator(s.lbKey(1), s.lbKey(1<<62))
+	}
+	if err != nil {
+		return -1, err
+	}
+	defer it.Close()
+
+	if !it.Valid() {
+		return -1, nil
+	}
+
+	var lb types.LightBlock
+	if err := json.Unmarshal(it.Value(), &lb); err != nil {
+		return -1, err
+	}
+	return lb.Height, nil
+}
+
+func (s *dbs) LightBlockBefore(height int64) (*types.LightBlock, error) {
+	if height <= 0 {
+		return nil, fmt.Errorf("height must be greater than 0")
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	it, err := s.db.ReverseIterator(s.lbKey(1), s.lbKey(height))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	if !it.Valid() {
+		return nil, provider.ErrLightBlockNotFound
+	}
+
+	var lb types.LightBlock
+	if err := json.Unmarshal(it.Value(), &lb); err != nil {
+		return nil, err
+	}
+	return &lb, nil
+}
+
+func (s *dbs) Prune(size uint16) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for s.size > size {
+		it, err := s.db.Iterator(s.lbKey(1), s.lbKey(1<<62))
+		if err != nil {
+			return err
+		}
+		if !it.Valid() {
+			it.Close()
+			break
+		}
+		key := it.Key()
+		it.Close()
+
+		if err := s.db.Delete(key); err != nil {
+			return err
+		}
+		s.size--
+	}
+	return s.db.Set(s.sizeKey(), mustJSON(s.size))
+}
+
+func (s *dbs) Size() uint16 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.size
+}
+
+func (s *dbs) lbKey(height int64) []byte {
+	return []byte(fmt.Sprintf("lb/%s/%020d", s.prefix, height))
+}
+
+func (s *dbs) sizeKey() []byte {
+	return []byte(fmt.Sprintf("lb/%s/%s", s.prefix, sizeKey))
+}
+
+func mustJSON(v interface{}) []byte {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
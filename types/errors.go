@@ -0,0 +1,16 @@
+package types
+
+import "fmt"
+
+// ErrNotEnoughVotingPowerSigned is returned when not enough validators/voters
+// signed a commit to reach the quorum (or trust level) required by the
+// caller.
+type ErrNotEnoughVotingPowerSigned struct {
+	Got    int64
+	Needed int64
+}
+
+func (e ErrNotEnoughVotingPowerSigned) Error() string {
+	return fmt.Sprintf("invalid commit -- insufficient voting power: got %d, needed more than %d",
+		e.Got, e.Needed)
+}
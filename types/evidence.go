@@ -0,0 +1,97 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+)
+
+// Evidence represents any provable malicious activity by a validator.
+type Evidence interface {
+	Height() int64          // height of the equivocation
+	Time() time.Time        // time of the equivocation
+	Bytes() []byte          // bytes which comprise the evidence
+	Hash() tmbytes.HexBytes // hash of the evidence
+	ValidateBasic() error   // basic consistency check
+	String() string         // string format of the evidence
+	Equal(ev Evidence) bool // check equality of evidence
+}
+
+// LightClientAttackEvidence is a generalized evidence that captures all
+// forms of known attacks on a light client such that a full node can
+// verify, propose and commit the evidence on-chain for appropriate
+// punishment to occur.
+//
+// ConflictingBlock is the light block that the light client received which,
+// for at least one full node it cross-checked with, didn't match its
+// locally verified chain. CommonHeight is the height at which the
+// conflicting block's signers are held accountable: for a lunatic attack,
+// that's the last height the two chains shared the same voter set; for an
+// equivocation attack (voters unchanged but a different block was signed),
+// it's the divergence height itself, where the two chains no longer agree.
+type LightClientAttackEvidence struct {
+	ConflictingBlock *LightBlock
+	CommonHeight     int64
+}
+
+var _ Evidence = &LightClientAttackEvidence{}
+
+// Height returns CommonHeight, the height at which the conflicting voter
+// set should be punished.
+func (l *LightClientAttackEvidence) Height() int64 {
+	return l.CommonHeight
+}
+
+// Time returns the time of the conflicting block.
+func (l *LightClientAttackEvidence) Time() time.Time {
+	return l.ConflictingBlock.Time
+}
+
+// Bytes returns a deterministic encoding identifying this piece of
+// evidence, used as the input to Hash.
+func (l *LightClientAttackEvidence) Bytes() []byte {
+	return []byte(fmt.Sprintf("%X/%d", l.ConflictingBlock.Hash(), l.CommonHeight))
+}
+
+// Hash returns the hash of the evidence.
+func (l *LightClientAttackEvidence) Hash() tmbytes.HexBytes {
+	return tmhash.Sum(l.Bytes())
+}
+
+// ValidateBasic performs basic validation such that the evidence can
+// become a part of the blockchain.
+func (l *LightClientAttackEvidence) ValidateBasic() error {
+	if l.ConflictingBlock == nil {
+		return fmt.Errorf("conflicting block cannot be nil")
+	}
+	if err := l.ConflictingBlock.ValidateBasic(l.ConflictingBlock.ChainID); err != nil {
+		return fmt.Errorf("invalid conflicting light block: %w", err)
+	}
+	if l.CommonHeight <= 0 {
+		return fmt.Errorf("common height must be positive")
+	}
+	if l.CommonHeight > l.ConflictingBlock.Height {
+		return fmt.Errorf("common height (%d) cannot exceed conflicting block height (%d)",
+			l.CommonHeight, l.ConflictingBlock.Height)
+	}
+	return nil
+}
+
+// String returns a string representation of the evidence.
+func (l *LightClientAttackEvidence) String() string {
+	return fmt.Sprintf("LightClientAttackEvidence{ConflictingBlock: %d, CommonHeight: %d}",
+		l.ConflictingBlock.Height, l.CommonHeight)
+}
+
+// Equal checks equality of evidence.
+func (l *LightClientAttackEvidence) Equal(ev Evidence) bool {
+	other, ok := ev.(*LightClientAttackEvidence)
+	if !ok {
+		return false
+	}
+	return l.CommonHeight == other.CommonHeight &&
+		bytes.Equal(l.ConflictingBlock.Hash(), other.ConflictingBlock.Hash())
+}
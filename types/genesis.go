@@ -49,13 +49,39 @@ type VoterParams struct {
 type GenesisDoc struct {
 	GenesisTime     time.Time          `json:"genesis_time"`
 	ChainID         string             `json:"chain_id"`
+	InitialHeight   int64              `json:"initial_height,omitempty"`
 	ConsensusParams *ConsensusParams   `json:"consensus_params,omitempty"`
 	Validators      []GenesisValidator `json:"validators,omitempty"`
 	VoterParams     *VoterParams       `json:"voter_params,omitempty"`
 	AppHash         tmbytes.HexBytes   `json:"app_hash"`
 	AppState        json.RawMessage    `json:"app_state,omitempty"`
+
+	// InitialHeader and InitialVoterSet together form an optional weak-
+	// subjectivity checkpoint: a signed header and the voter set that
+	// produced it, trusted out-of-band by whoever authored the genesis
+	// file. They let a node doing a chain upgrade or a state-sync bootstrap
+	// start directly at InitialHeight instead of having to replay or
+	// separately distribute a trust hash for height 1. When set,
+	// InitialHeader.Height must equal InitialHeight-1.
+	InitialHeader   *SignedHeader `json:"initial_header,omitempty"`
+	InitialVoterSet *VoterSet     `json:"initial_voter_set,omitempty"`
+
+	// InitialEvidence carries evidence of misbehavior that was already
+	// detected and committed to on a prior instance of this chain, so that
+	// slashing can still occur after a coordinated restart that resets the
+	// evidence pool.
+	InitialEvidence []Evidence `json:"initial_evidence,omitempty"`
 }
 
+// NOTE: delaying ResponseEndBlock.ValidatorUpdates activation to H+2 so
+// that a header can self-certify the voter set it was signed by (via a
+// NextVoterHash field on Header/State) is blocked on this tree: it needs
+// changes to state/execution.go, state/state.go, types/block.go and
+// types/validator_set.go, none of which exist in this version of the
+// codebase, plus a state-store schema bump. GenesisDoc has nothing to
+// contribute here until that groundwork lands, so it's left out rather
+// than landed as a disconnected helper with no caller.
+
 // SaveAs is a utility method for saving GenensisDoc as a JSON file.
 func (genDoc *GenesisDoc) SaveAs(file string) error {
 	genDocBytes, err := cdc.MarshalJSONIndent(genDoc, "", "  ")
@@ -85,6 +111,12 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 		return errors.Errorf("chain_id in genesis doc is too long (max: %d)", MaxChainIDLen)
 	}
 
+	if genDoc.InitialHeight < 0 {
+		return errors.Errorf("initial_height cannot be negative (got %d)", genDoc.InitialHeight)
+	} else if genDoc.InitialHeight == 0 {
+		genDoc.InitialHeight = 1
+	}
+
 	if genDoc.ConsensusParams == nil {
 		genDoc.ConsensusParams = DefaultConsensusParams()
 	} else if err := genDoc.ConsensusParams.Validate(); err != nil {
@@ -113,6 +145,42 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 		genDoc.GenesisTime = tmtime.Now()
 	}
 
+	if (genDoc.InitialHeader == nil) != (genDoc.InitialVoterSet == nil) {
+		return errors.New("initial_header and initial_voter_set must either both be set or both be empty")
+	}
+
+	if genDoc.InitialHeader != nil {
+		if err := genDoc.InitialHeader.ValidateBasic(genDoc.ChainID); err != nil {
+			return errors.Wrap(err, "invalid initial_header")
+		}
+		if genDoc.InitialHeader.Height != genDoc.InitialHeight-1 {
+			return errors.Errorf("initial_header height (%d) must be initial_height-1 (%d)",
+				genDoc.InitialHeader.Height, genDoc.InitialHeight-1)
+		}
+		// NOTE: InitialHeader.ValidatorsHash commits to the full candidate
+		// validator set, not the elected voter subset, so it can't be
+		// compared against InitialVoterSet.Hash() directly -- the two only
+		// coincide when every validator is also a voter. Properly verifying
+		// that InitialVoterSet was actually elected for InitialHeader needs
+		// a voters-commitment field on Header that doesn't exist in this
+		// version of the codebase, so that cross-check is left to the
+		// caller for now; this only checks that a voter set was supplied at
+		// all.
+		if len(genDoc.InitialVoterSet.Hash()) == 0 {
+			return errors.New("initial_voter_set must hash to a non-empty value")
+		}
+	}
+
+	for i, ev := range genDoc.InitialEvidence {
+		if err := ev.ValidateBasic(); err != nil {
+			return errors.Wrapf(err, "invalid initial_evidence[%d]", i)
+		}
+		if genDoc.InitialHeader != nil && ev.Time().Before(genDoc.InitialHeader.Time) {
+			return errors.Errorf("initial_evidence[%d] (time %v) is older than the initial_header checkpoint (time %v)",
+				i, ev.Time(), genDoc.InitialHeader.Time)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func randGenesisDoc() *GenesisDoc {
+	pubKey := ed25519.GenPrivKey().PubKey()
+	return &GenesisDoc{
+		GenesisTime: time.Now().Round(0),
+		ChainID:     "test-chain",
+		Validators: []GenesisValidator{
+			{Address: pubKey.Address(), PubKey: pubKey, Power: 10, Name: "val-1"},
+		},
+		AppHash: []byte("app-hash"),
+	}
+}
+
+func TestGenesisDocValidateAndComplete_InitialHeight(t *testing.T) {
+	g := randGenesisDoc()
+	require.NoError(t, g.ValidateAndComplete())
+	assert.EqualValues(t, 1, g.InitialHeight, "initial_height should default to 1")
+
+	g = randGenesisDoc()
+	g.InitialHeight = 1000
+	require.NoError(t, g.ValidateAndComplete())
+	assert.EqualValues(t, 1000, g.InitialHeight)
+
+	g = randGenesisDoc()
+	g.InitialHeight = -1
+	assert.Error(t, g.ValidateAndComplete())
+}
+
+func TestGenesisDocValidateAndComplete_InitialCheckpoint(t *testing.T) {
+	valSet := NewValidatorSet([]*Validator{NewValidator(ed25519.GenPrivKey().PubKey(), 10)})
+	voterSet := SelectVoter(valSet, []byte("proof"), DefaultVoterParams())
+	header := &Header{
+		ChainID:        "test-chain",
+		Height:         999,
+		Time:           time.Now().Round(0),
+		ValidatorsHash: voterSet.Hash(),
+	}
+
+	t.Run("accepts a consistent checkpoint", func(t *testing.T) {
+		g := randGenesisDoc()
+		g.InitialHeight = 1000
+		g.InitialHeader = &SignedHeader{Header: header}
+		g.InitialVoterSet = voterSet
+		require.NoError(t, g.ValidateAndComplete())
+	})
+
+	t.Run("rejects a checkpoint at the wrong height", func(t *testing.T) {
+		g := randGenesisDoc()
+		g.InitialHeight = 5 // header.Height (999) != InitialHeight-1
+		g.InitialHeader = &SignedHeader{Header: header}
+		g.InitialVoterSet = voterSet
+		assert.Error(t, g.ValidateAndComplete())
+	})
+
+	t.Run("rejects a header without its voter set", func(t *testing.T) {
+		g := randGenesisDoc()
+		g.InitialHeight = 1000
+		g.InitialHeader = &SignedHeader{Header: header}
+		assert.Error(t, g.ValidateAndComplete())
+	})
+
+	t.Run("rejects evidence older than the checkpoint", func(t *testing.T) {
+		g := randGenesisDoc()
+		g.InitialHeight = 1000
+		g.InitialHeader = &SignedHeader{Header: header}
+		g.InitialVoterSet = voterSet
+		g.InitialEvidence = []Evidence{
+			&LightClientAttackEvidence{
+				ConflictingBlock: &LightBlock{
+					SignedHeader: &SignedHeader{Header: &Header{
+						ChainID: "test-chain",
+						Height:  10,
+						Time:    header.Time.Add(-1 * time.Hour),
+					}},
+					ValidatorSet: valSet,
+				},
+				CommonHeight: 9,
+			},
+		}
+		assert.Error(t, g.ValidateAndComplete())
+	})
+}
+
+func TestGenesisDocFromJSON_RoundTrip(t *testing.T) {
+	g := randGenesisDoc()
+	g.InitialHeight = 42
+
+	bz, err := cdc.MarshalJSONIndent(g, "", "  ")
+	require.NoError(t, err)
+
+	got, err := GenesisDocFromJSON(bz)
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, got.InitialHeight)
+	assert.Equal(t, g.ChainID, got.ChainID)
+}